@@ -6,19 +6,12 @@ import (
 	"github.com/prysmaticlabs/go-ssz"
 
 	"github.com/boltdb/bolt"
-	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
 )
 
 func createAttesterSlashing(enc []byte) (*ethpb.AttesterSlashing, error) {
-	protoSlashing := &ethpb.AttesterSlashing{}
-
-	err := proto.Unmarshal(enc, protoSlashing)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal encoding")
-	}
-	return protoSlashing, nil
+	return decodeVersionedAttesterSlashing(enc)
 }
 
 // AttesterSlashings accepts a status and returns all slashings with this status.
@@ -82,16 +75,23 @@ func (db *Store) DeleteAttesterSlashingWithStatus(status SlashingStatus, atteste
 	if err != nil {
 		return errors.Wrap(err, "failed to get hash root of attesterSlashing")
 	}
+	filter, err := attesterSlashingBloomFilter(db)
+	if err != nil {
+		return errors.Wrap(err, "failed to load attester slashing bloom filter")
+	}
 	return db.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(slashingBucket)
 		k := encodeStatusTypeRoot(status, SlashingType(Attestation), root)
-		if err != nil {
-			return errors.Wrap(err, "failed to get key for for attester slashing.")
+		if bucket.Get(k) == nil {
+			// Nothing stored under this status: either it was never saved or
+			// a concurrent status change has already moved it elsewhere. The
+			// index still reflects the real record, so leave it alone.
+			return nil
 		}
 		if err := bucket.Delete(k); err != nil {
 			return errors.Wrap(err, "failed to delete the slashing proof from slashing bucket")
 		}
-		return nil
+		return deleteSlashingIndex(tx, filter, root)
 	})
 }
 
@@ -101,20 +101,29 @@ func (db *Store) DeleteAttesterSlashing(slashing *ethpb.AttesterSlashing) error
 	if err != nil {
 		return errors.Wrap(err, "failed to get hash root of attester slashing")
 	}
+	filter, err := attesterSlashingBloomFilter(db)
+	if err != nil {
+		return errors.Wrap(err, "failed to load attester slashing bloom filter")
+	}
+	status, slashingType, found := SlashingStatus(0), SlashingType(0), false
 	err = db.update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(slashingBucket)
-		b.ForEach(func(k, v []byte) error {
-			if bytes.HasSuffix(k, root[:]) {
-				b.Delete(k)
-			}
+		status, slashingType, found = getSlashingIndex(tx, root)
+		if !found {
 			return nil
-		})
-		return nil
+		}
+		b := tx.Bucket(slashingBucket)
+		if err := b.Delete(encodeStatusTypeRoot(status, slashingType, root)); err != nil {
+			return errors.Wrap(err, "failed to delete the slashing proof from slashing bucket")
+		}
+		return deleteSlashingIndex(tx, filter, root)
 	})
 	return err
 }
 
-// HasAttesterSlashing returns the slashing key if it is found in db.
+// HasAttesterSlashing reports whether the slashing is in db and, if so, its
+// current status. It
+// consults the in-memory bloom filter first, so the common case of a
+// negative lookup never touches bolt.
 func (db *Store) HasAttesterSlashing(slashing *ethpb.AttesterSlashing) (bool, SlashingStatus, error) {
 	root, err := ssz.HashTreeRoot(slashing)
 	var status SlashingStatus
@@ -122,16 +131,17 @@ func (db *Store) HasAttesterSlashing(slashing *ethpb.AttesterSlashing) (bool, Sl
 	if err != nil {
 		return found, status, errors.Wrap(err, "failed to get hash root of attesterSlashing")
 	}
+	filter, err := attesterSlashingBloomFilter(db)
+	if err != nil {
+		return found, status, errors.Wrap(err, "failed to load attester slashing bloom filter")
+	}
+	if !filter.mightContain(root) {
+		return false, status, nil
+	}
 	err = db.view(func(tx *bolt.Tx) error {
-		b := tx.Bucket(slashingBucket)
-		c := b.Cursor()
-		for k, _ := c.First(); k != nil; k, _ = c.Next() {
-			if bytes.HasSuffix(k, root[:]) {
-				found = true
-				status = SlashingStatus(k[0])
-				return nil
-			}
-		}
+		st, _, ok := getSlashingIndex(tx, root)
+		found = ok
+		status = st
 		return nil
 	})
 	return found, status, err
@@ -144,31 +154,28 @@ func (db *Store) updateAttesterSlashingStatus(slashing *ethpb.AttesterSlashing,
 	if err != nil {
 		return errors.Wrap(err, "failed to get hash root of attesterSlashing")
 	}
+	filter, err := attesterSlashingBloomFilter(db)
+	if err != nil {
+		return errors.Wrap(err, "failed to load attester slashing bloom filter")
+	}
 	err = db.update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(slashingBucket)
-		var keysToDelete [][]byte
-		c := b.Cursor()
-		for k, _ := c.First(); k != nil; k, _ = c.Next() {
-			if bytes.HasSuffix(k, root[:]) {
-				keysToDelete = append(keysToDelete, k)
-			}
-		}
-		for _, k := range keysToDelete {
-			err = b.Delete(k)
-			if err != nil {
+		if oldStatus, oldType, found := getSlashingIndex(tx, root); found {
+			if err := b.Delete(encodeStatusTypeRoot(oldStatus, oldType, root)); err != nil {
 				return err
 			}
-
 		}
-		enc, err := proto.Marshal(slashing)
+		enc, err := defaultSlashingCodec.EncodeAttesterSlashing(slashing)
 		if err != nil {
 			return errors.Wrap(err, "failed to marshal")
 		}
-		err = b.Put(encodeStatusTypeRoot(status, SlashingType(Attestation), root), enc)
-		return err
+		if err := b.Put(encodeStatusTypeRoot(status, SlashingType(Attestation), root), enc); err != nil {
+			return err
+		}
+		return putSlashingIndex(tx, filter, root, status, SlashingType(Attestation))
 	})
 	if err != nil {
 		return err
 	}
-	return err
+	return maybePersistBloomFilter(db, filter)
 }
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestDeleteAttesterSlashingWithStatus_IgnoresStaleStatus(t *testing.T) {
+	db := setupDB(t)
+	slashing := testAttesterSlashing(0)
+	if err := db.SaveAttesterSlashing(Active, slashing); err != nil {
+		t.Fatal(err)
+	}
+
+	// A stale caller still thinks the slashing is Included, but it is
+	// actually stored as Active; the delete should be a no-op rather than
+	// erasing the index entry for the record that is still physically
+	// present.
+	if err := db.DeleteAttesterSlashingWithStatus(Included, slashing); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, status, err := db.HasAttesterSlashing(slashing); err != nil {
+		t.Fatal(err)
+	} else if !found {
+		t.Fatal("expected slashing to still be present after a delete with a stale status")
+	} else if status != Active {
+		t.Fatalf("expected slashing to still have status Active, got %v", status)
+	}
+	if found, err := indexHasRoot(db, slashing); err != nil {
+		t.Fatal(err)
+	} else if !found {
+		t.Fatal("expected the index entry to survive a delete with a stale status")
+	}
+
+	if err := db.DeleteAttesterSlashingWithStatus(Active, slashing); err != nil {
+		t.Fatal(err)
+	}
+	if found, _, err := db.HasAttesterSlashing(slashing); err != nil {
+		t.Fatal(err)
+	} else if found {
+		t.Fatal("expected slashing to be gone after deleting with its real status")
+	}
+	if found, err := indexHasRoot(db, slashing); err != nil {
+		t.Fatal(err)
+	} else if found {
+		t.Fatal("expected the index entry to be removed once the matching status was used")
+	}
+}
@@ -0,0 +1,296 @@
+package db
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sync"
+
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+)
+
+// slashingIndexBucket maps a 32-byte SSZ hash-tree-root directly to its
+// status and type, so Has/Delete/Update no longer need to scan slashingBucket.
+var slashingIndexBucket = []byte("slashing-index")
+
+// slashingMetaBucket holds housekeeping values for the slashing store, such
+// as the persisted bloom filter blob.
+var slashingMetaBucket = []byte("slashing-meta")
+
+var bloomFilterKey = []byte("attester-slashing-bloom")
+
+// slashingIndexGenerationKey tracks how many times slashingIndexBucket has
+// been mutated. A persisted bloom filter blob embeds the generation it was
+// built at; if that no longer matches the bucket's counter, the blob is
+// stale and must not be trusted, since an unmatched stale blob can report a
+// false *negative* (unlike a normal bloom filter, whose only approximation
+// error is false positives).
+var slashingIndexGenerationKey = []byte("attester-slashing-index-generation")
+
+// bloomFilterBitsPerEntry and bloomFilterHashCount give roughly a 1% false
+// positive rate, which is acceptable since a positive only costs one extra
+// bolt.Get against slashingIndexBucket.
+const (
+	bloomFilterBitsPerEntry = 10
+	bloomFilterHashCount    = 7
+	minBloomFilterBits      = 1 << 16 // 64Kb floor so small DBs stay cheap to rebuild.
+
+	// bloomPersistInterval bounds how often the full bit array is written to
+	// bolt: every bloomPersistInterval saves or status changes, not on every
+	// single one, since the blob can be tens of MiB for a multi-million-entry
+	// DB. Deletes do not count towards the interval.
+	bloomPersistInterval = 200
+)
+
+// rotatingBloomFilter is a fixed-size bit array addressed by rotating an FNV
+// hash of the root bloomFilterHashCount times, in the style of most
+// space-efficient bloom filter implementations (e.g. martin/hashfilter).
+type rotatingBloomFilter struct {
+	mu                 sync.RWMutex
+	bits               []byte
+	size               uint64
+	generation         uint64
+	writesSincePersist int
+}
+
+func newRotatingBloomFilter(expectedEntries uint64) *rotatingBloomFilter {
+	size := expectedEntries * bloomFilterBitsPerEntry
+	if size < minBloomFilterBits {
+		size = minBloomFilterBits
+	}
+	return &rotatingBloomFilter{
+		bits: make([]byte, (size+7)/8),
+		size: size,
+	}
+}
+
+func (f *rotatingBloomFilter) indices(root [32]byte) []uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(root[:])
+	base := h.Sum64()
+	indices := make([]uint64, bloomFilterHashCount)
+	for i := 0; i < bloomFilterHashCount; i++ {
+		base = base*1099511628211 ^ uint64(i)
+		indices[i] = base % f.size
+	}
+	return indices
+}
+
+func (f *rotatingBloomFilter) add(root [32]byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, idx := range f.indices(root) {
+		f.bits[idx/8] |= 1 << (idx % 8)
+	}
+}
+
+// mightContain returns false only when root is definitely absent from the
+// index bucket. A true result still requires a bolt lookup to confirm.
+func (f *rotatingBloomFilter) mightContain(root [32]byte) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, idx := range f.indices(root) {
+		if f.bits[idx/8]&(1<<(idx%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// setGeneration records the slashingIndexBucket generation this filter's
+// contents are accurate as of.
+func (f *rotatingBloomFilter) setGeneration(gen uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.generation = gen
+}
+
+// notePendingWrite increments the persist-debt counter and reports whether
+// it has crossed bloomPersistInterval, resetting it if so.
+func (f *rotatingBloomFilter) notePendingWrite() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writesSincePersist++
+	if f.writesSincePersist >= bloomPersistInterval {
+		f.writesSincePersist = 0
+		return true
+	}
+	return false
+}
+
+func (f *rotatingBloomFilter) marshal() []byte {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	buf := make([]byte, 16+len(f.bits))
+	binary.LittleEndian.PutUint64(buf[:8], f.generation)
+	binary.LittleEndian.PutUint64(buf[8:16], f.size)
+	copy(buf[16:], f.bits)
+	return buf
+}
+
+func (f *rotatingBloomFilter) unmarshal(enc []byte) error {
+	if len(enc) < 16 {
+		return errors.New("bloom filter blob too short")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.generation = binary.LittleEndian.Uint64(enc[:8])
+	f.size = binary.LittleEndian.Uint64(enc[8:16])
+	f.bits = append([]byte(nil), enc[16:]...)
+	return nil
+}
+
+// attesterSlashingBloomFilter lazily builds or loads the bloom filter used to
+// short-circuit negative HasAttesterSlashing lookups for db, caching it on db
+// itself so it is released when the Store is closed.
+func attesterSlashingBloomFilter(db *Store) (*rotatingBloomFilter, error) {
+	db.bloomMu.Lock()
+	if db.bloomFilter != nil {
+		f := db.bloomFilter
+		db.bloomMu.Unlock()
+		return f, nil
+	}
+	db.bloomMu.Unlock()
+
+	f, err := loadBloomFromDisk(db)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		f, err = buildBloomFromIndex(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	db.bloomMu.Lock()
+	db.bloomFilter = f
+	db.bloomMu.Unlock()
+	return f, nil
+}
+
+// loadBloomFromDisk returns the previously persisted bloom filter blob, or
+// nil if none has been saved yet (e.g. a fresh DB, one from before this
+// feature existed, or one whose blob is stale relative to the index's
+// current generation counter) so the caller falls back to
+// buildBloomFromIndex instead of trusting a blob that could be missing
+// roots committed after it was last persisted.
+func loadBloomFromDisk(db *Store) (*rotatingBloomFilter, error) {
+	var enc []byte
+	var currentGen uint64
+	err := db.view(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(slashingMetaBucket)
+		if meta == nil {
+			return nil
+		}
+		currentGen = readSlashingIndexGeneration(meta)
+		v := meta.Get(bloomFilterKey)
+		if v != nil {
+			enc = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read persisted bloom filter")
+	}
+	if enc == nil {
+		return nil, nil
+	}
+	f := newRotatingBloomFilter(0)
+	if err := f.unmarshal(enc); err != nil {
+		return nil, err
+	}
+	if f.generation != currentGen {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// buildBloomFromIndex populates a fresh bloom filter by iterating
+// slashingIndexBucket once, so a restart never requires a full scan of the
+// much larger slashingBucket. It reads the index's generation counter in
+// the same transaction as the scan, so the filter it returns is tagged with
+// the generation it is actually accurate for.
+func buildBloomFromIndex(db *Store) (*rotatingBloomFilter, error) {
+	var roots [][32]byte
+	var gen uint64
+	err := db.view(func(tx *bolt.Tx) error {
+		if meta := tx.Bucket(slashingMetaBucket); meta != nil {
+			gen = readSlashingIndexGeneration(meta)
+		}
+		idx := tx.Bucket(slashingIndexBucket)
+		if idx == nil {
+			return nil
+		}
+		return idx.ForEach(func(k, _ []byte) error {
+			if len(k) != 32 {
+				return nil
+			}
+			var root [32]byte
+			copy(root[:], k)
+			roots = append(roots, root)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build bloom filter from slashing index")
+	}
+	f := newRotatingBloomFilter(uint64(len(roots)))
+	for _, root := range roots {
+		f.add(root)
+	}
+	f.setGeneration(gen)
+	return f, nil
+}
+
+// persistBloomFilter saves the bloom filter blob under slashingMetaBucket so
+// a restart can skip buildBloomFromIndex while the blob's generation still
+// matches the index. There is no DB-size threshold: the blob is written and
+// used whatever the size of the DB.
+func persistBloomFilter(db *Store, f *rotatingBloomFilter) error {
+	return db.update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(slashingMetaBucket)
+		if err != nil {
+			return err
+		}
+		return meta.Put(bloomFilterKey, f.marshal())
+	})
+}
+
+// maybePersistBloomFilter persists f only once every bloomPersistInterval
+// mutations, so a hot slasher doesn't re-serialize and write the whole bit
+// array on every single save/status change.
+func maybePersistBloomFilter(db *Store, f *rotatingBloomFilter) error {
+	if !f.notePendingWrite() {
+		return nil
+	}
+	return persistBloomFilter(db, f)
+}
+
+// readSlashingIndexGeneration reads the current index generation counter
+// from an already-open slashingMetaBucket.
+func readSlashingIndexGeneration(meta *bolt.Bucket) uint64 {
+	v := meta.Get(slashingIndexGenerationKey)
+	if len(v) != 8 {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(v)
+}
+
+// bumpSlashingIndexGeneration increments and persists the index generation
+// counter within tx, returning the new value. It must be called in the same
+// transaction as any slashingIndexBucket mutation, so the counter and the
+// index stay consistent even if the process crashes immediately after.
+func bumpSlashingIndexGeneration(tx *bolt.Tx) (uint64, error) {
+	meta, err := tx.CreateBucketIfNotExists(slashingMetaBucket)
+	if err != nil {
+		return 0, err
+	}
+	gen := readSlashingIndexGeneration(meta) + 1
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, gen)
+	if err := meta.Put(slashingIndexGenerationKey, buf); err != nil {
+		return 0, err
+	}
+	return gen, nil
+}
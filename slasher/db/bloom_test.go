@@ -0,0 +1,138 @@
+package db
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/prysmaticlabs/go-ssz"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+func TestRotatingBloomFilter_MightContain(t *testing.T) {
+	f := newRotatingBloomFilter(1000)
+	var present [32]byte
+	present[0] = 1
+	var absent [32]byte
+	absent[0] = 2
+
+	if f.mightContain(present) {
+		t.Fatal("expected empty filter to not contain root")
+	}
+	f.add(present)
+	if !f.mightContain(present) {
+		t.Fatal("expected filter to contain root after add")
+	}
+	if f.mightContain(absent) {
+		t.Fatal("expected filter to not contain a root that was never added (false positive)")
+	}
+}
+
+func TestRotatingBloomFilter_MarshalUnmarshal(t *testing.T) {
+	f := newRotatingBloomFilter(1000)
+	var root [32]byte
+	root[0] = 42
+	f.add(root)
+
+	enc := f.marshal()
+	restored := newRotatingBloomFilter(0)
+	if err := restored.unmarshal(enc); err != nil {
+		t.Fatal(err)
+	}
+	if !restored.mightContain(root) {
+		t.Fatal("expected restored filter to contain root present before marshaling")
+	}
+}
+
+// linearScanHasAttesterSlashing reimplements the pre-index O(N) ForEach scan
+// HasAttesterSlashing used to do, so the indexed/bloom-filtered
+// implementation can be checked against it directly.
+func linearScanHasAttesterSlashing(db *Store, slashing *ethpb.AttesterSlashing) (bool, SlashingStatus, error) {
+	root, err := ssz.HashTreeRoot(slashing)
+	if err != nil {
+		return false, 0, err
+	}
+	var found bool
+	var status SlashingStatus
+	err = db.view(func(tx *bolt.Tx) error {
+		c := tx.Bucket(slashingBucket).Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			if bytes.HasSuffix(k, root[:]) {
+				found = true
+				status = SlashingStatus(k[0])
+				return nil
+			}
+		}
+		return nil
+	})
+	return found, status, err
+}
+
+func TestHasAttesterSlashing_MatchesLinearScan(t *testing.T) {
+	db := setupDB(t)
+
+	saved := make([]*ethpb.AttesterSlashing, 0, 10)
+	for i := uint64(0); i < 10; i++ {
+		s := testAttesterSlashing(i)
+		if err := db.SaveAttesterSlashing(Active, s); err != nil {
+			t.Fatal(err)
+		}
+		saved = append(saved, s)
+	}
+
+	for _, s := range saved {
+		wantFound, wantStatus, err := linearScanHasAttesterSlashing(db, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotFound, gotStatus, err := db.HasAttesterSlashing(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotFound != wantFound || gotStatus != wantStatus {
+			t.Fatalf("indexed HasAttesterSlashing = (%v, %v), linear scan = (%v, %v)", gotFound, gotStatus, wantFound, wantStatus)
+		}
+	}
+
+	absent := testAttesterSlashing(999)
+	wantFound, _, err := linearScanHasAttesterSlashing(db, absent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotFound, _, err := db.HasAttesterSlashing(absent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotFound != wantFound {
+		t.Fatalf("expected indexed and linear-scan lookups to agree on an absent slashing, got %v want %v", gotFound, wantFound)
+	}
+
+	if err := db.DeleteAttesterSlashing(saved[0]); err != nil {
+		t.Fatal(err)
+	}
+	if found, _, err := db.HasAttesterSlashing(saved[0]); err != nil {
+		t.Fatal(err)
+	} else if found {
+		t.Fatal("expected deleted slashing to no longer be found")
+	}
+	if found, _, err := linearScanHasAttesterSlashing(db, saved[0]); err != nil {
+		t.Fatal(err)
+	} else if found {
+		t.Fatal("expected deleted slashing to no longer be found by a linear scan either")
+	}
+}
+
+func BenchmarkRotatingBloomFilter_MightContain(b *testing.B) {
+	f := newRotatingBloomFilter(100000)
+	var root [32]byte
+	for i := 0; i < 100000; i++ {
+		root[0] = byte(i)
+		root[1] = byte(i >> 8)
+		f.add(root)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.mightContain(root)
+	}
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/gogo/protobuf/proto"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+// TestSlashingCodec_RoundTrip fuzzes proto -> ssz -> proto to guarantee the
+// schema migration never changes what an attester slashing decodes to.
+func TestSlashingCodec_RoundTrip(t *testing.T) {
+	f := func(seed int64) bool {
+		slashing := randomAttesterSlashing(seed)
+
+		protoEnc, err := proto.Marshal(slashing)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decodedFromProto, err := decodeVersionedAttesterSlashing(append([]byte{schemaVersionProto}, protoEnc...))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sszEnc, err := defaultSlashingCodec.EncodeAttesterSlashing(decodedFromProto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decodedFromSSZ, err := decodeVersionedAttesterSlashing(sszEnc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return proto.Equal(slashing, decodedFromSSZ)
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
+
+func randomAttesterSlashing(seed int64) *ethpb.AttesterSlashing {
+	slot := uint64(seed) % 1000000
+	return &ethpb.AttesterSlashing{
+		Attestation_1: &ethpb.IndexedAttestation{
+			AttestingIndices: []uint64{uint64(seed % 100), uint64((seed + 1) % 100)},
+			Data: &ethpb.AttestationData{
+				Slot:            slot,
+				CommitteeIndex:  uint64(seed % 64),
+				BeaconBlockRoot: make([]byte, 32),
+			},
+		},
+		Attestation_2: &ethpb.IndexedAttestation{
+			AttestingIndices: []uint64{uint64(seed % 100)},
+			Data: &ethpb.AttestationData{
+				Slot:            slot,
+				CommitteeIndex:  uint64(seed % 64),
+				BeaconBlockRoot: make([]byte, 32),
+			},
+		},
+	}
+}
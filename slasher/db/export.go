@@ -0,0 +1,318 @@
+package db
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+
+	"github.com/boltdb/bolt"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+// slashingFileMagic identifies a slashing export/import stream. The format
+// is append-only and concatenable: two exports can be `cat`'d together and
+// re-imported, which is what lets deltas ship between nodes.
+var slashingFileMagic = [4]byte{'s', 'l', 's', 'h'}
+
+const slashingFileVersion byte = 1
+
+// importCursorKey records the byte offset ImportSlashings has successfully
+// consumed from its input stream, so a paused or killed import resumes by
+// skipping forward rather than re-processing (and re-counting in its stats)
+// records it already saved.
+var importCursorKey = []byte("attester-slashing-import-cursor")
+
+// importCursorPersistInterval bounds how often the offset is written to
+// bolt: every N records, not one bolt.Update per record.
+const importCursorPersistInterval = 100
+
+// ExportFilter narrows which attester slashings ExportSlashings writes.
+// A zero-value filter exports every slashing.
+type ExportFilter struct {
+	Statuses []SlashingStatus
+}
+
+func (f ExportFilter) matches(status SlashingStatus) bool {
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, s := range f.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportMode controls how ImportSlashings handles a record whose root
+// already exists in the DB.
+type ImportMode uint8
+
+const (
+	// ImportSkipExisting leaves an existing slashing's status untouched.
+	ImportSkipExisting ImportMode = iota
+	// ImportOverwriteStatus replaces an existing slashing's status with the
+	// one recorded in the imported record.
+	ImportOverwriteStatus
+)
+
+// ImportStats summarizes the result of an ImportSlashings call.
+type ImportStats struct {
+	Imported int
+	Skipped  int
+	Invalid  int
+}
+
+// record type tags, kept distinct from SlashingType so the export format is
+// not coupled to slashingBucket's internal key layout.
+//
+// Scope note: this package only stores attester slashings, so only
+// recordTypeAttesterSlashing is produced or consumed today. The tag byte is
+// reserved space for proposer/block-header slashing records once this
+// package grows a store for them; ImportSlashings already treats any other
+// tag as a forward-compatible unknown rather than a format error (see
+// below), so adding recordTypeProposerSlashing later won't require a format
+// version bump.
+const (
+	recordTypeAttesterSlashing uint8 = 1
+)
+
+// ExportSlashings streams every attester slashing matching filter to w as a
+// sequence of length-prefixed, SSZ-encoded records:
+//
+//	magic(4) | version(1) | type(1) | status(1) | length(4, LE) | ssz payload
+//
+// The stream is append-only: concatenating two export files (or writing one
+// export after another with the same w) produces a valid, larger stream.
+func (db *Store) ExportSlashings(w io.Writer, filter ExportFilter) error {
+	bw := bufio.NewWriter(w)
+	err := db.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(slashingBucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			status := SlashingStatus(k[0])
+			if !filter.matches(status) {
+				return nil
+			}
+			slashing, err := decodeVersionedAttesterSlashing(v)
+			if err != nil {
+				return errors.Wrap(err, "failed to decode attester slashing for export")
+			}
+			payload, err := ssz.Marshal(slashing)
+			if err != nil {
+				return errors.Wrap(err, "failed to ssz marshal attester slashing for export")
+			}
+			return writeSlashingRecord(bw, recordTypeAttesterSlashing, status, payload)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func writeSlashingRecord(w io.Writer, recordType uint8, status SlashingStatus, payload []byte) error {
+	header := make([]byte, 4+1+1+1+4)
+	copy(header[:4], slashingFileMagic[:])
+	header[4] = slashingFileVersion
+	header[5] = recordType
+	header[6] = byte(status)
+	binary.LittleEndian.PutUint32(header[7:], uint32(len(payload)))
+	if _, err := w.Write(header); err != nil {
+		return errors.Wrap(err, "failed to write slashing record header")
+	}
+	if _, err := w.Write(payload); err != nil {
+		return errors.Wrap(err, "failed to write slashing record payload")
+	}
+	return nil
+}
+
+// countingReader tracks how many bytes have been read through it, so
+// ImportSlashings can persist a resumable byte offset without the caller's
+// io.Reader needing to support io.Seeker.
+type countingReader struct {
+	r     io.Reader
+	nread uint64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.nread += uint64(n)
+	return n, err
+}
+
+// ImportSlashings reads a stream produced by ExportSlashings and saves each
+// valid attester slashing record. Every record is validated before being
+// saved: its SSZ hash-tree-root must recompute cleanly and the two
+// attestations must actually satisfy a slashable condition, so a corrupt or
+// malicious export can't poison the DB with nonsense entries.
+//
+// Import resumes from the byte offset persisted under slashingMetaBucket by
+// a previous, interrupted call: r is expected to be positioned at the start
+// of the same stream, and the already-imported prefix is discarded before
+// processing continues. The cursor is cleared once the stream is fully
+// consumed.
+func (db *Store) ImportSlashings(r io.Reader, mode ImportMode) (ImportStats, error) {
+	var stats ImportStats
+	offset, err := db.loadImportCursor()
+	if err != nil {
+		return stats, errors.Wrap(err, "failed to load import cursor")
+	}
+	// cr, not a buffered reader, is read directly so its byte count always
+	// lands exactly on a record boundary and can be persisted as a resume
+	// point without over-reporting bytes a bufio.Reader had buffered ahead
+	// but the caller had not yet processed.
+	cr := &countingReader{r: r}
+	if offset > 0 {
+		if _, err := io.CopyN(ioutil.Discard, cr, int64(offset)); err != nil {
+			return stats, errors.Wrap(err, "failed to skip already-imported prefix")
+		}
+	}
+
+	sinceLastPersist := 0
+	for {
+		// recordStart is the offset of the record about to be read; every
+		// record before it has been fully processed.
+		recordStart := cr.nread
+		header := make([]byte, 4+1+1+1+4)
+		_, err := io.ReadFull(cr, header)
+		if err == io.EOF {
+			return stats, db.clearImportCursor()
+		}
+		if err != nil {
+			return stats, errors.Wrap(err, "failed to read slashing record header")
+		}
+		if string(header[:4]) != string(slashingFileMagic[:]) {
+			return stats, errors.New("invalid slashing export file: bad magic")
+		}
+		if header[4] != slashingFileVersion {
+			return stats, errors.Errorf("unsupported slashing export version %d", header[4])
+		}
+		recordType := header[5]
+		status := SlashingStatus(header[6])
+		length := binary.LittleEndian.Uint32(header[7:])
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(cr, payload); err != nil {
+			return stats, errors.Wrap(err, "failed to read slashing record payload")
+		}
+
+		// The cursor is persisted as the start of this record, not its end:
+		// every record before it has been saved, skipped or rejected, while
+		// this one has not been handled yet, so a resumed import re-reads it
+		// rather than losing it.
+		sinceLastPersist++
+		if sinceLastPersist >= importCursorPersistInterval {
+			sinceLastPersist = 0
+			if err := db.saveImportCursor(recordStart); err != nil {
+				return stats, errors.Wrap(err, "failed to persist import cursor")
+			}
+		}
+
+		if recordType != recordTypeAttesterSlashing {
+			// Unknown record types are skipped rather than failing the whole
+			// import, so a future record type added to the format doesn't
+			// break older import tooling reading a mixed-version stream.
+			stats.Skipped++
+			continue
+		}
+
+		slashing := &ethpb.AttesterSlashing{}
+		if err := ssz.Unmarshal(payload, slashing); err != nil {
+			stats.Invalid++
+			continue
+		}
+		if !isSlashableAttesterSlashing(slashing) {
+			stats.Invalid++
+			continue
+		}
+		root, err := ssz.HashTreeRoot(slashing)
+		if err != nil {
+			stats.Invalid++
+			continue
+		}
+
+		found, existingStatus, err := db.HasAttesterSlashing(slashing)
+		if err != nil {
+			return stats, errors.Wrap(err, "failed to check for existing attester slashing during import")
+		}
+		if found {
+			if mode == ImportSkipExisting {
+				stats.Skipped++
+				continue
+			}
+			if mode == ImportOverwriteStatus && existingStatus == status {
+				stats.Skipped++
+				continue
+			}
+		}
+		_ = root
+		if err := db.SaveAttesterSlashing(status, slashing); err != nil {
+			return stats, errors.Wrap(err, "failed to save imported attester slashing")
+		}
+		stats.Imported++
+	}
+}
+
+// isSlashableAttesterSlashing checks the two-attestation slashable condition
+// (surrounding or double vote) rather than trusting the exporter.
+func isSlashableAttesterSlashing(slashing *ethpb.AttesterSlashing) bool {
+	att1, att2 := slashing.GetAttestation_1(), slashing.GetAttestation_2()
+	if att1 == nil || att2 == nil || att1.Data == nil || att2.Data == nil {
+		return false
+	}
+	d1, d2 := att1.Data, att2.Data
+	if d1.Target == nil || d2.Target == nil || d1.Source == nil || d2.Source == nil {
+		return false
+	}
+	if d1.Target.Epoch == d2.Target.Epoch && !proto.Equal(d1, d2) {
+		return true
+	}
+	isSurround := (d1.Source.Epoch < d2.Source.Epoch && d2.Target.Epoch < d1.Target.Epoch) ||
+		(d2.Source.Epoch < d1.Source.Epoch && d1.Target.Epoch < d2.Target.Epoch)
+	return isSurround
+}
+
+func (db *Store) loadImportCursor() (uint64, error) {
+	var offset uint64
+	err := db.view(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(slashingMetaBucket)
+		if meta == nil {
+			return nil
+		}
+		if v := meta.Get(importCursorKey); len(v) == 8 {
+			offset = binary.LittleEndian.Uint64(v)
+		}
+		return nil
+	})
+	return offset, err
+}
+
+func (db *Store) saveImportCursor(offset uint64) error {
+	return db.update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(slashingMetaBucket)
+		if err != nil {
+			return err
+		}
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, offset)
+		return meta.Put(importCursorKey, buf)
+	})
+}
+
+func (db *Store) clearImportCursor() error {
+	return db.update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(slashingMetaBucket)
+		if meta == nil {
+			return nil
+		}
+		return meta.Delete(importCursorKey)
+	})
+}
@@ -0,0 +1,216 @@
+package db
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/prysmaticlabs/go-ssz"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+func TestExportImportSlashings_RoundTrip(t *testing.T) {
+	src := setupDB(t)
+	slashings := []*ethpb.AttesterSlashing{
+		testAttesterSlashing(0),
+		testAttesterSlashing(1),
+		testAttesterSlashing(2),
+	}
+	statuses := []SlashingStatus{Active, Included, Active}
+	for i, s := range slashings {
+		if err := src.SaveAttesterSlashing(statuses[i], s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := src.ExportSlashings(&buf, ExportFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := setupDB(t)
+	stats, err := dst.ImportSlashings(bytes.NewReader(buf.Bytes()), ImportSkipExisting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Imported != len(slashings) {
+		t.Fatalf("expected %d imported records, got %d", len(slashings), stats.Imported)
+	}
+	if stats.Invalid != 0 || stats.Skipped != 0 {
+		t.Fatalf("expected a clean import, got %+v", stats)
+	}
+
+	for i, s := range slashings {
+		found, status, err := dst.HasAttesterSlashing(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found {
+			t.Fatalf("expected slashing %d to be present after import", i)
+		}
+		if status != statuses[i] {
+			t.Fatalf("expected slashing %d to have status %v, got %v", i, statuses[i], status)
+		}
+	}
+
+	// Re-importing the same stream with ImportSkipExisting should not error
+	// and should report every record as skipped rather than re-saved.
+	stats, err = dst.ImportSlashings(bytes.NewReader(buf.Bytes()), ImportSkipExisting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Skipped != len(slashings) {
+		t.Fatalf("expected re-import to skip all %d existing records, got %+v", len(slashings), stats)
+	}
+}
+
+func TestImportSlashings_ResumesFromPersistedCursor(t *testing.T) {
+	src := setupDB(t)
+	slashings := []*ethpb.AttesterSlashing{testAttesterSlashing(10), testAttesterSlashing(11)}
+	for _, s := range slashings {
+		if err := src.SaveAttesterSlashing(Active, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := src.ExportSlashings(&buf, ExportFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	full := buf.Bytes()
+
+	// Compute the exact byte length of the first record so the resume point
+	// lands on a record boundary, the way a real persisted cursor would.
+	firstRecordLen := 4 + 1 + 1 + 1 + 4 + int(binary.LittleEndian.Uint32(full[7:11]))
+
+	// ExportSlashings walks slashingBucket in key order, and both records
+	// share the same status/type prefix, so the one with the smaller root is
+	// written first.
+	root0, err := ssz.HashTreeRoot(slashings[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	root1, err := ssz.HashTreeRoot(slashings[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	skipped, resumed := slashings[0], slashings[1]
+	if bytes.Compare(root1[:], root0[:]) < 0 {
+		skipped, resumed = slashings[1], slashings[0]
+	}
+
+	dst := setupDB(t)
+	// Manually pretend a previous run already consumed the first record.
+	if err := dst.saveImportCursor(uint64(firstRecordLen)); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := dst.ImportSlashings(bytes.NewReader(full), ImportSkipExisting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Imported != 1 {
+		t.Fatalf("expected resuming import to process only the second record, got %+v", stats)
+	}
+	if found, _, err := dst.HasAttesterSlashing(skipped); err != nil {
+		t.Fatal(err)
+	} else if found {
+		t.Fatal("expected the first record to have been skipped over by the resume offset, not imported")
+	}
+	if found, _, err := dst.HasAttesterSlashing(resumed); err != nil {
+		t.Fatal(err)
+	} else if !found {
+		t.Fatal("expected the second record to have been imported after resuming")
+	}
+
+	offset, err := dst.loadImportCursor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != 0 {
+		t.Fatal("expected a clean EOF to clear the import cursor")
+	}
+}
+
+func TestWriteSlashingRecord_RoundTripsHeader(t *testing.T) {
+	var buf bytes.Buffer
+	payload := []byte{1, 2, 3, 4}
+	if err := writeSlashingRecord(&buf, recordTypeAttesterSlashing, SlashingStatus(2), payload); err != nil {
+		t.Fatal(err)
+	}
+
+	header := buf.Bytes()[:11]
+	if string(header[:4]) != string(slashingFileMagic[:]) {
+		t.Error("expected magic bytes to round trip")
+	}
+	if header[4] != slashingFileVersion {
+		t.Error("expected version byte to round trip")
+	}
+	if header[5] != recordTypeAttesterSlashing {
+		t.Error("expected record type to round trip")
+	}
+	if header[6] != byte(2) {
+		t.Error("expected status byte to round trip")
+	}
+	if !bytes.Equal(buf.Bytes()[11:], payload) {
+		t.Error("expected payload to round trip unchanged")
+	}
+}
+
+func TestIsSlashableAttesterSlashing(t *testing.T) {
+	doubleVote := &ethpb.AttesterSlashing{
+		Attestation_1: &ethpb.IndexedAttestation{
+			Data: &ethpb.AttestationData{
+				Slot:   1,
+				Source: &ethpb.Checkpoint{Epoch: 1},
+				Target: &ethpb.Checkpoint{Epoch: 2},
+			},
+		},
+		Attestation_2: &ethpb.IndexedAttestation{
+			Data: &ethpb.AttestationData{
+				Slot:   2,
+				Source: &ethpb.Checkpoint{Epoch: 1},
+				Target: &ethpb.Checkpoint{Epoch: 2},
+			},
+		},
+	}
+	if !isSlashableAttesterSlashing(doubleVote) {
+		t.Error("expected two attestations for the same target epoch at different slots to be slashable")
+	}
+
+	surround := &ethpb.AttesterSlashing{
+		Attestation_1: &ethpb.IndexedAttestation{
+			Data: &ethpb.AttestationData{
+				Source: &ethpb.Checkpoint{Epoch: 1},
+				Target: &ethpb.Checkpoint{Epoch: 5},
+			},
+		},
+		Attestation_2: &ethpb.IndexedAttestation{
+			Data: &ethpb.AttestationData{
+				Source: &ethpb.Checkpoint{Epoch: 2},
+				Target: &ethpb.Checkpoint{Epoch: 4},
+			},
+		},
+	}
+	if !isSlashableAttesterSlashing(surround) {
+		t.Error("expected a surrounding vote to be slashable")
+	}
+
+	notSlashable := &ethpb.AttesterSlashing{
+		Attestation_1: &ethpb.IndexedAttestation{
+			Data: &ethpb.AttestationData{
+				Source: &ethpb.Checkpoint{Epoch: 1},
+				Target: &ethpb.Checkpoint{Epoch: 2},
+			},
+		},
+		Attestation_2: &ethpb.IndexedAttestation{
+			Data: &ethpb.AttestationData{
+				Source: &ethpb.Checkpoint{Epoch: 2},
+				Target: &ethpb.Checkpoint{Epoch: 3},
+			},
+		},
+	}
+	if isSlashableAttesterSlashing(notSlashable) {
+		t.Error("expected two unrelated attestations to not be slashable")
+	}
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"github.com/boltdb/bolt"
+)
+
+// indexValue packs a slashing's status and type into the two-byte value
+// stored under its root in slashingIndexBucket, mirroring the layout already
+// used as the key prefix in slashingBucket.
+func indexValue(status SlashingStatus, slashingType SlashingType) []byte {
+	return []byte{byte(status), byte(slashingType)}
+}
+
+// putSlashingIndex records root -> status/type in slashingIndexBucket,
+// marks root present in the in-memory bloom filter, and bumps the index
+// generation counter in the same transaction so a persisted bloom filter
+// blob that predates this write is recognized as stale on next load.
+func putSlashingIndex(tx *bolt.Tx, f *rotatingBloomFilter, root [32]byte, status SlashingStatus, slashingType SlashingType) error {
+	idx, err := tx.CreateBucketIfNotExists(slashingIndexBucket)
+	if err != nil {
+		return err
+	}
+	if err := idx.Put(root[:], indexValue(status, slashingType)); err != nil {
+		return err
+	}
+	gen, err := bumpSlashingIndexGeneration(tx)
+	if err != nil {
+		return err
+	}
+	f.add(root)
+	f.setGeneration(gen)
+	return nil
+}
+
+// deleteSlashingIndex removes root from slashingIndexBucket and bumps the
+// index generation counter. The bloom filter bits are intentionally not
+// cleared: false positives only cost an extra bolt.Get, whereas rebuilding
+// the filter on every delete would defeat it. f's generation is still
+// advanced to match, so persisting f afterward records an accurate
+// generation even though its bits are now a (harmless) superset.
+func deleteSlashingIndex(tx *bolt.Tx, f *rotatingBloomFilter, root [32]byte) error {
+	idx := tx.Bucket(slashingIndexBucket)
+	if idx == nil {
+		return nil
+	}
+	if err := idx.Delete(root[:]); err != nil {
+		return err
+	}
+	gen, err := bumpSlashingIndexGeneration(tx)
+	if err != nil {
+		return err
+	}
+	if f != nil {
+		f.setGeneration(gen)
+	}
+	return nil
+}
+
+// getSlashingIndex returns the status/type recorded for root, if any.
+func getSlashingIndex(tx *bolt.Tx, root [32]byte) (status SlashingStatus, slashingType SlashingType, found bool) {
+	idx := tx.Bucket(slashingIndexBucket)
+	if idx == nil {
+		return status, slashingType, false
+	}
+	v := idx.Get(root[:])
+	if v == nil || len(v) != 2 {
+		return status, slashingType, false
+	}
+	return SlashingStatus(v[0]), SlashingType(v[1]), true
+}
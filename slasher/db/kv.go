@@ -0,0 +1,65 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+const slashingDBName = "slasher.db"
+
+// Store is the bolt-backed persistence layer for slasher slashing proofs.
+type Store struct {
+	db           *bolt.DB
+	databasePath string
+
+	bloomMu     sync.Mutex
+	bloomFilter *rotatingBloomFilter
+}
+
+// NewKVStore initializes a slasher Store rooted at dirPath, creating the
+// database file and its buckets if they do not already exist.
+func NewKVStore(dirPath string) (*Store, error) {
+	if err := os.MkdirAll(dirPath, 0700); err != nil {
+		return nil, err
+	}
+	boltDB, err := bolt.Open(filepath.Join(dirPath, slashingDBName), 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	store := &Store{db: boltDB, databasePath: dirPath}
+	err = store.db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{slashingBucket, slashingIndexBucket, slashingMetaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		boltDB.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// DatabasePath returns the directory this Store was opened from.
+func (db *Store) DatabasePath() string {
+	return db.databasePath
+}
+
+// Close shuts down the underlying bolt database.
+func (db *Store) Close() error {
+	return db.db.Close()
+}
+
+func (db *Store) view(fn func(tx *bolt.Tx) error) error {
+	return db.db.View(fn)
+}
+
+func (db *Store) update(fn func(tx *bolt.Tx) error) error {
+	return db.db.Update(fn)
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+)
+
+// migrateBatchSize bounds how many records are rewritten per bolt
+// transaction, so migrating a large slashing DB doesn't hold a single write
+// lock for the whole pass.
+const migrateBatchSize = 1000
+
+// MigrationOption configures RunSlashingMigrations.
+type MigrationOption func(*migrationConfig)
+
+type migrationConfig struct {
+	skip bool
+}
+
+// SkipSlashingMigration disables the v0 (protobuf) -> v1 (SSZ) rewrite.
+// Read-only tools (e.g. a block explorer or a `prysmctl` inspection command)
+// should pass this so they never write to a DB they only meant to read.
+func SkipSlashingMigration() MigrationOption {
+	return func(c *migrationConfig) {
+		c.skip = true
+	}
+}
+
+// RunSlashingMigrations rewrites every v0 (protobuf) attester slashing
+// record in slashingBucket to the v1 (SSZ) schema. It is safe to call on a
+// DB that has already been migrated, or one that has none of the legacy
+// records: it is a no-op in both cases. Callers should invoke this once at
+// Store open time, before the bloom filter is built, so the index and the
+// filter only ever observe the current schema version.
+func RunSlashingMigrations(db *Store, opts ...MigrationOption) error {
+	cfg := &migrationConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.skip {
+		return nil
+	}
+
+	for {
+		n, err := migrateAttesterSlashingBatch(db)
+		if err != nil {
+			return errors.Wrap(err, "failed to migrate attester slashings to ssz schema")
+		}
+		if n < migrateBatchSize {
+			return nil
+		}
+	}
+}
+
+// migrateAttesterSlashingBatch rewrites up to migrateBatchSize legacy
+// records in a single bolt transaction and reports how many it migrated.
+func migrateAttesterSlashingBatch(db *Store) (int, error) {
+	migrated := 0
+	err := db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(slashingBucket)
+		if b == nil {
+			return nil
+		}
+		type legacyRecord struct {
+			key     []byte
+			decoded []byte
+		}
+		var legacy []legacyRecord
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if len(legacy) >= migrateBatchSize {
+				break
+			}
+			if len(v) > 0 && v[0] == schemaVersionSSZ {
+				continue
+			}
+			legacy = append(legacy, legacyRecord{key: append([]byte(nil), k...), decoded: append([]byte(nil), v...)})
+		}
+		for _, rec := range legacy {
+			slashing, err := decodeVersionedAttesterSlashing(rec.decoded)
+			if err != nil {
+				return errors.Wrap(err, "failed to decode legacy attester slashing during migration")
+			}
+			enc, err := defaultSlashingCodec.EncodeAttesterSlashing(slashing)
+			if err != nil {
+				return errors.Wrap(err, "failed to re-encode attester slashing as ssz")
+			}
+			if err := b.Put(rec.key, enc); err != nil {
+				return err
+			}
+		}
+		migrated = len(legacy)
+		return nil
+	})
+	return migrated, err
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/gogo/protobuf/proto"
+	"github.com/prysmaticlabs/go-ssz"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+// putLegacyProtoSlashing writes slashing directly as an unversioned protobuf
+// record, bypassing SaveAttesterSlashing, to simulate a DB written before
+// the SSZ schema existed.
+func putLegacyProtoSlashing(t *testing.T, db *Store, status SlashingStatus, slashing *ethpb.AttesterSlashing) [32]byte {
+	t.Helper()
+	root, err := ssz.HashTreeRoot(slashing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := proto.Marshal(slashing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(slashingBucket)
+		if err := b.Put(encodeStatusTypeRoot(status, SlashingType(Attestation), root), enc); err != nil {
+			return err
+		}
+		return putSlashingIndex(tx, mustBloomFilter(t, db), root, status, SlashingType(Attestation))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func mustBloomFilter(t *testing.T, db *Store) *rotatingBloomFilter {
+	t.Helper()
+	f, err := attesterSlashingBloomFilter(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestRunSlashingMigrations_RewritesLegacyProtoRecords(t *testing.T) {
+	db := setupDB(t)
+
+	legacy := testAttesterSlashing(0)
+	root := putLegacyProtoSlashing(t, db, Active, legacy)
+
+	if err := RunSlashingMigrations(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var enc []byte
+	err := db.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(slashingBucket)
+		enc = b.Get(encodeStatusTypeRoot(Active, SlashingType(Attestation), root))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(enc) == 0 {
+		t.Fatal("expected migrated record to still be present under its key")
+	}
+	if enc[0] != schemaVersionSSZ {
+		t.Fatalf("expected migrated record to carry the ssz schema version byte, got %d", enc[0])
+	}
+
+	migrated, err := decodeVersionedAttesterSlashing(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(legacy, migrated) {
+		t.Fatal("expected migrated record to decode to the same slashing as before migration")
+	}
+
+	// Migrating an already-migrated DB should be a no-op, not an error.
+	if err := RunSlashingMigrations(db); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunSlashingMigrations_SkipOption(t *testing.T) {
+	db := setupDB(t)
+	root := putLegacyProtoSlashing(t, db, Active, testAttesterSlashing(1))
+
+	if err := RunSlashingMigrations(db, SkipSlashingMigration()); err != nil {
+		t.Fatal(err)
+	}
+
+	var enc []byte
+	err := db.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(slashingBucket)
+		enc = b.Get(encodeStatusTypeRoot(Active, SlashingType(Attestation), root))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(enc) == 0 || enc[0] == schemaVersionSSZ {
+		t.Fatal("expected SkipSlashingMigration to leave the legacy record untouched")
+	}
+}
@@ -0,0 +1,309 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+// pruneCursorKey stores the last root processed by a prune pass, so a crash
+// mid-prune resumes rather than rescanning from the start.
+var pruneCursorKey = []byte("attester-slashing-prune-cursor")
+
+// pruneBatchSize bounds how many deletes happen per bolt transaction, so a
+// large prune never holds the write lock long enough to stall writers.
+//
+// pruneScanBatchSize separately bounds how many keys a single transaction
+// examines at all: in the common case where most slashings are retained,
+// pruneBatchSize's delete-count threshold alone would never trip, and a
+// transaction would scan (and hold the write lock over) the entire bucket.
+const (
+	pruneBatchSize     = 1000
+	pruneScanBatchSize = 5000
+)
+
+// PrunePolicy describes which attester slashings PruneSlashings is allowed
+// to remove.
+type PrunePolicy struct {
+	// TerminalStatuses are the statuses (e.g. Included, Applied) a slashing
+	// must have to even be considered for pruning.
+	TerminalStatuses []SlashingStatus
+	// RetentionEpochs is the number of epochs, counted back from
+	// CurrentEpoch, that a terminal slashing is kept regardless of status.
+	RetentionEpochs uint64
+	// CurrentEpoch anchors the retention window.
+	CurrentEpoch uint64
+}
+
+// PruneProgress is sent on the channel passed to PruneSlashings so callers
+// can surface prune metrics while a long pass is running.
+type PruneProgress struct {
+	Scanned  int
+	Retained int
+	Deleted  int
+	Done     bool
+}
+
+func (p PrunePolicy) isTerminal(status SlashingStatus) bool {
+	for _, s := range p.TerminalStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (p PrunePolicy) withinRetentionWindow(targetEpoch uint64) bool {
+	if p.CurrentEpoch < p.RetentionEpochs {
+		return true
+	}
+	return targetEpoch >= p.CurrentEpoch-p.RetentionEpochs
+}
+
+// PruneSlashings deletes attester slashings that are both in a terminal
+// status and whose attestation target epoch falls outside the retention
+// window described by policy. progress, if non-nil, receives one update per
+// batch and is closed when the prune finishes or ctx is canceled.
+//
+// The first pass streams slashingBucket once to build a bloom filter of
+// roots that MUST be retained; the second pass deletes any key whose root
+// is absent from that filter, in bounded batches so long write locks are
+// avoided. A cursor is persisted under slashingMetaBucket after every batch
+// so a crash mid-prune resumes instead of restarting.
+func (db *Store) PruneSlashings(ctx context.Context, policy PrunePolicy, progress chan<- PruneProgress) error {
+	if progress != nil {
+		defer close(progress)
+	}
+
+	retain, err := db.buildRetainedRootsFilter(policy)
+	if err != nil {
+		return errors.Wrap(err, "failed to build retained-roots filter for pruning")
+	}
+
+	filter, err := attesterSlashingBloomFilter(db)
+	if err != nil {
+		return errors.Wrap(err, "failed to load attester slashing bloom filter")
+	}
+
+	cursor, err := db.loadPruneCursor()
+	if err != nil {
+		return errors.Wrap(err, "failed to load prune cursor")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		scanned, retained, deleted, next, done, err := db.pruneBatch(retain, filter, cursor)
+		if err != nil {
+			return errors.Wrap(err, "failed to prune attester slashing batch")
+		}
+		cursor = next
+		if err := db.savePruneCursor(cursor); err != nil {
+			return errors.Wrap(err, "failed to persist prune cursor")
+		}
+		if progress != nil {
+			progress <- PruneProgress{Scanned: scanned, Retained: retained, Deleted: deleted, Done: done}
+		}
+		if done {
+			if err := persistBloomFilter(db, filter); err != nil {
+				return errors.Wrap(err, "failed to persist bloom filter after pruning")
+			}
+			return db.clearPruneCursor()
+		}
+	}
+}
+
+// buildRetainedRootsFilter streams slashingBucket once and returns a bloom
+// filter containing every root that must survive pruning: anything not in a
+// terminal status, or terminal but inside the retention window.
+func (db *Store) buildRetainedRootsFilter(policy PrunePolicy) (*rotatingBloomFilter, error) {
+	var retained [][32]byte
+	err := db.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(slashingBucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			status := SlashingStatus(k[0])
+			slashing, err := decodeVersionedAttesterSlashing(v)
+			if err != nil {
+				return err
+			}
+			targetEpoch := attesterSlashingTargetEpoch(slashing)
+			if !policy.isTerminal(status) || policy.withinRetentionWindow(targetEpoch) {
+				var root [32]byte
+				copy(root[:], k[len(k)-32:])
+				retained = append(retained, root)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	f := newRotatingBloomFilter(uint64(len(retained)))
+	for _, root := range retained {
+		f.add(root)
+	}
+	return f, nil
+}
+
+// attesterSlashingTargetEpoch returns the later of the two conflicting
+// attestations' target epochs, matching the epoch a slasher would use to
+// decide whether the slashing is still within its retention window.
+func attesterSlashingTargetEpoch(slashing *ethpb.AttesterSlashing) uint64 {
+	var epoch uint64
+	if att := slashing.GetAttestation_1(); att != nil && att.Data != nil && att.Data.Target != nil {
+		epoch = att.Data.Target.Epoch
+	}
+	if att := slashing.GetAttestation_2(); att != nil && att.Data != nil && att.Data.Target != nil {
+		if att.Data.Target.Epoch > epoch {
+			epoch = att.Data.Target.Epoch
+		}
+	}
+	return epoch
+}
+
+// pruneDeletion pairs a slashingBucket key with the root it was indexed
+// under, since deleteSlashingIndex needs the bare root rather than the full
+// status||type||root key.
+type pruneDeletion struct {
+	key  []byte
+	root [32]byte
+}
+
+// pruneBatch deletes up to pruneBatchSize keys, starting after cursor, whose
+// root is not present in retain. It also stops after scanning
+// pruneScanBatchSize keys even if nothing was queued for deletion, so a
+// steady state where most slashings are retained can't hold the write lock
+// over the entire bucket in one transaction.
+func (db *Store) pruneBatch(retain, filter *rotatingBloomFilter, cursor []byte) (scanned, retained, deleted int, next []byte, done bool, err error) {
+	err = db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(slashingBucket)
+		if b == nil {
+			done = true
+			return nil
+		}
+		c := b.Cursor()
+		var k, v []byte
+		if cursor == nil {
+			k, v = c.First()
+		} else {
+			k, v = c.Seek(cursor)
+			if k != nil && string(k) == string(cursor) {
+				k, v = c.Next()
+			}
+		}
+		var toDelete []pruneDeletion
+		for ; k != nil; k, v = c.Next() {
+			_ = v
+			scanned++
+			var root [32]byte
+			copy(root[:], k[len(k)-32:])
+			if retain.mightContain(root) {
+				retained++
+			} else {
+				toDelete = append(toDelete, pruneDeletion{key: append([]byte(nil), k...), root: root})
+				deleted++
+			}
+			next = append([]byte(nil), k...)
+			if len(toDelete) >= pruneBatchSize || scanned >= pruneScanBatchSize {
+				break
+			}
+		}
+		for _, d := range toDelete {
+			if err := b.Delete(d.key); err != nil {
+				return err
+			}
+			if err := deleteSlashingIndex(tx, filter, d.root); err != nil {
+				return err
+			}
+		}
+		if k == nil {
+			done = true
+		}
+		return nil
+	})
+	return scanned, retained, deleted, next, done, err
+}
+
+func (db *Store) loadPruneCursor() ([]byte, error) {
+	var cursor []byte
+	err := db.view(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(slashingMetaBucket)
+		if meta == nil {
+			return nil
+		}
+		if v := meta.Get(pruneCursorKey); v != nil {
+			cursor = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	return cursor, err
+}
+
+func (db *Store) savePruneCursor(cursor []byte) error {
+	if cursor == nil {
+		return nil
+	}
+	return db.update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(slashingMetaBucket)
+		if err != nil {
+			return err
+		}
+		return meta.Put(pruneCursorKey, cursor)
+	})
+}
+
+func (db *Store) clearPruneCursor() error {
+	return db.update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(slashingMetaBucket)
+		if meta == nil {
+			return nil
+		}
+		return meta.Delete(pruneCursorKey)
+	})
+}
+
+// CompactAfterPrune reclaims the disk space bolt's free-list holds onto
+// after a prune by copying live pages into a fresh file with bolt.Compact
+// and atomically renaming it over the original, so PruneSlashings actually
+// shrinks the DB on disk instead of just freeing internal pages for reuse.
+func (db *Store) CompactAfterPrune() error {
+	srcPath := filepath.Join(db.DatabasePath(), slashingDBName)
+	dstPath := srcPath + ".compact"
+
+	dst, err := bolt.Open(dstPath, 0600, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to open compaction destination db")
+	}
+
+	if err := bolt.Compact(dst, db.db, 0); err != nil {
+		dst.Close()
+		os.Remove(dstPath)
+		return errors.Wrap(err, "failed to compact slashing db")
+	}
+	if err := dst.Close(); err != nil {
+		return errors.Wrap(err, "failed to close compacted db")
+	}
+	if err := db.db.Close(); err != nil {
+		return errors.Wrap(err, "failed to close original db before swap")
+	}
+	if err := os.Rename(dstPath, srcPath); err != nil {
+		return errors.Wrap(err, "failed to swap compacted db into place")
+	}
+	reopened, err := bolt.Open(srcPath, 0600, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to reopen db after compaction")
+	}
+	db.db = reopened
+	return nil
+}
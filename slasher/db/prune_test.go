@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/prysmaticlabs/go-ssz"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+func TestPrunePolicy_WithinRetentionWindow(t *testing.T) {
+	policy := PrunePolicy{RetentionEpochs: 100, CurrentEpoch: 1000}
+
+	if policy.withinRetentionWindow(800) {
+		t.Error("expected epoch older than the retention window to not be retained")
+	}
+	if !policy.withinRetentionWindow(950) {
+		t.Error("expected epoch inside the retention window to be retained")
+	}
+	earlyPolicy := PrunePolicy{RetentionEpochs: 100, CurrentEpoch: 50}
+	if !earlyPolicy.withinRetentionWindow(0) {
+		t.Error("expected the window to retain everything before CurrentEpoch passes RetentionEpochs")
+	}
+}
+
+func TestPrunePolicy_IsTerminal(t *testing.T) {
+	policy := PrunePolicy{TerminalStatuses: []SlashingStatus{2, 3}}
+
+	if !policy.isTerminal(2) {
+		t.Error("expected status 2 to be terminal")
+	}
+	if policy.isTerminal(1) {
+		t.Error("expected status 1 to not be terminal")
+	}
+}
+
+func TestPruneSlashings_DeletesTerminalOutsideWindow(t *testing.T) {
+	db := setupDB(t)
+
+	terminalOld := []*ethpb.AttesterSlashing{testAttesterSlashing(0), testAttesterSlashing(1), testAttesterSlashing(4)}
+	for _, s := range terminalOld {
+		if err := db.SaveAttesterSlashing(Included, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	retained := []*ethpb.AttesterSlashing{testAttesterSlashing(2), testAttesterSlashing(3)}
+	for _, s := range retained {
+		if err := db.SaveAttesterSlashing(Active, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	policy := PrunePolicy{
+		TerminalStatuses: []SlashingStatus{Included},
+		RetentionEpochs:  3,
+		CurrentEpoch:     10,
+	}
+	if err := db.PruneSlashings(context.Background(), policy, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range terminalOld {
+		if found, _, err := db.HasAttesterSlashing(s); err != nil {
+			t.Fatal(err)
+		} else if found {
+			t.Error("expected a terminal slashing outside the retention window to be pruned")
+		}
+		if found, err := indexHasRoot(db, s); err != nil {
+			t.Fatal(err)
+		} else if found {
+			t.Error("expected slashingIndexBucket to no longer have an entry for a pruned slashing")
+		}
+	}
+	for _, s := range retained {
+		if found, _, err := db.HasAttesterSlashing(s); err != nil {
+			t.Fatal(err)
+		} else if !found {
+			t.Error("expected a non-terminal slashing to survive pruning regardless of its epoch")
+		}
+	}
+}
+
+func TestCompactAfterPrune_ReopensUsableDB(t *testing.T) {
+	db := setupDB(t)
+	saved := testAttesterSlashing(0)
+	if err := db.SaveAttesterSlashing(Active, saved); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.CompactAfterPrune(); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, status, err := db.HasAttesterSlashing(saved); err != nil {
+		t.Fatal(err)
+	} else if !found {
+		t.Fatal("expected slashing saved before compaction to survive it")
+	} else if status != Active {
+		t.Fatalf("expected status Active after compaction, got %v", status)
+	}
+
+	another := testAttesterSlashing(1)
+	if err := db.SaveAttesterSlashing(Active, another); err != nil {
+		t.Fatal(err)
+	}
+	if found, _, err := db.HasAttesterSlashing(another); err != nil {
+		t.Fatal(err)
+	} else if !found {
+		t.Fatal("expected the compacted db to still accept writes after reopening")
+	}
+}
+
+// indexHasRoot reports whether slashingIndexBucket still has an entry for s,
+// bypassing the bloom filter so a pruned-but-still-indexed bug is visible
+// even though HasAttesterSlashing's filter would also (correctly) report
+// false for a pruned root once the filter is rebuilt.
+func indexHasRoot(db *Store, s *ethpb.AttesterSlashing) (bool, error) {
+	root, err := ssz.HashTreeRoot(s)
+	if err != nil {
+		return false, err
+	}
+	var found bool
+	err = db.view(func(tx *bolt.Tx) error {
+		_, _, ok := getSlashingIndex(tx, root)
+		found = ok
+		return nil
+	})
+	return found, err
+}
@@ -0,0 +1,43 @@
+package db
+
+// slashingBucket stores encoded slashing proofs keyed by
+// status || type || hash-tree-root.
+var slashingBucket = []byte("slashing")
+
+// SlashingStatus describes the lifecycle of a slashing proof the slasher
+// has observed.
+type SlashingStatus uint8
+
+const (
+	// Active slashings have been detected but not yet acted on.
+	Active SlashingStatus = iota
+	// Included slashings have been included in a beacon block.
+	Included
+	// Reverted slashings were included but later reverted, e.g. by a reorg.
+	Reverted
+)
+
+// SlashingType distinguishes the kind of slashable offense a proof records.
+type SlashingType uint8
+
+const (
+	// Attestation identifies an attester slashing proof.
+	Attestation = iota
+	// Proposal identifies a proposer slashing proof.
+	Proposal
+)
+
+// encodeStatusType returns the shared key prefix used to group slashings in
+// slashingBucket by status and type.
+func encodeStatusType(status SlashingStatus, slashingType SlashingType) []byte {
+	return []byte{byte(status), byte(slashingType)}
+}
+
+// encodeStatusTypeRoot returns the full slashingBucket key for a slashing
+// proof: its status/type prefix followed by its hash-tree-root.
+func encodeStatusTypeRoot(status SlashingStatus, slashingType SlashingType, root [32]byte) []byte {
+	key := make([]byte, 0, 2+32)
+	key = append(key, encodeStatusType(status, slashingType)...)
+	key = append(key, root[:]...)
+	return key
+}
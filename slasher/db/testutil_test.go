@@ -0,0 +1,58 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	ethpb "github.com/prysmaticlabs/ethereumapis/eth/v1alpha1"
+)
+
+// setupDB returns a Store backed by a fresh bolt database in a temporary
+// directory, closed and cleaned up automatically at the end of the test.
+func setupDB(t *testing.T) *Store {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "slasher-db-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewKVStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := store.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return store
+}
+
+// testAttesterSlashing builds a distinct, valid (slashable) attester
+// slashing for index i, suitable for exercising Save/Has/Delete without
+// every test needing to hand-construct ethpb types.
+func testAttesterSlashing(i uint64) *ethpb.AttesterSlashing {
+	return &ethpb.AttesterSlashing{
+		Attestation_1: &ethpb.IndexedAttestation{
+			AttestingIndices: []uint64{i, i + 1},
+			Data: &ethpb.AttestationData{
+				Slot:            i,
+				BeaconBlockRoot: make([]byte, 32),
+				Source:          &ethpb.Checkpoint{Epoch: i},
+				Target:          &ethpb.Checkpoint{Epoch: i + 2},
+			},
+		},
+		Attestation_2: &ethpb.IndexedAttestation{
+			AttestingIndices: []uint64{i},
+			Data: &ethpb.AttestationData{
+				Slot:            i + 1,
+				BeaconBlockRoot: make([]byte, 32),
+				Source:          &ethpb.Checkpoint{Epoch: i + 1},
+				Target:          &ethpb.Checkpoint{Epoch: i + 2},
+			},
+		},
+	}
+}